@@ -20,6 +20,10 @@ import (
 const (
 	DefaultNonce     uint64 = 1
 	KeyStateRootHash        = "STATEROOTHASH"
+
+	// DefaultBlockGasLimit is the block gas limit used by the test block
+	// header that contract tx's are processed against.
+	DefaultBlockGasLimit uint64 = 4700000
 )
 
 // preprocessContract creates the contract tx dependent state DB, blockchain store
@@ -81,9 +85,20 @@ func processContract(statedb *state.Statedb, bcStore store.BlockchainStore, tx *
 		CreateTimestamp:   big.NewInt(time.Now().Unix()),
 		Nonce:             DefaultNonce,
 		ExtraData:         make([]byte, 0),
+		GasLimit:          DefaultBlockGasLimit,
 	}
 
 	evmContext := core.NewEVMContext(tx, header, header.Creator, bcStore)
 
-	return core.ProcessContract(evmContext, tx, 0, statedb, &vm.Config{})
+	receipt, err := core.ProcessContract(evmContext, tx, 0, statedb, &vm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	header.GasUsed = receipt.GasUsed
+	if err := header.ValidateGasUsed(); err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
 }
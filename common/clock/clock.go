@@ -0,0 +1,54 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package clock
+
+import "time"
+
+// Clock abstracts time.Now so that callers (most notably test fixtures such as
+// core/simulated.Backend) can fast-forward or pin the current time instead of
+// depending on the wall clock.
+type Clock interface {
+	// Now returns the current time as seen by the clock.
+	Now() time.Time
+}
+
+// realClock is a Clock backed by the actual wall clock.
+type realClock struct{}
+
+// New returns a Clock backed by the system wall clock.
+func New() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Mock is a Clock whose value is only ever advanced explicitly, which makes
+// it suitable for deterministic tests that need to fast-forward timestamps.
+type Mock struct {
+	now time.Time
+}
+
+// NewMock returns a Mock clock initialized to the given time.
+func NewMock(now time.Time) *Mock {
+	return &Mock{now: now}
+}
+
+// Now returns the clock's current time.
+func (m *Mock) Now() time.Time {
+	return m.now
+}
+
+// Add advances the clock by the given duration.
+func (m *Mock) Add(d time.Duration) {
+	m.now = m.now.Add(d)
+}
+
+// Set pins the clock to the given time.
+func (m *Mock) Set(now time.Time) {
+	m.now = now
+}
@@ -0,0 +1,129 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/database"
+)
+
+// GenesisAccount describes the pre-funded state of a single account written
+// into the genesis state trie before the state root is computed.
+type GenesisAccount struct {
+	Balance *big.Int                   // Balance is the initial account balance
+	Nonce   uint64                     // Nonce is the initial account nonce
+	Code    []byte                     // Code is the initial contract code, nil for externally owned accounts
+	Storage map[common.Hash]common.Hash // Storage is the initial contract storage, nil for externally owned accounts
+}
+
+// GenesisInfo is the genesis information of the blockchain.
+type GenesisInfo struct {
+	// Difficulty is the difficulty of the genesis block.
+	Difficulty int64
+
+	// ShardNumber is the shard number of the genesis block.
+	ShardNumber uint
+
+	// CreateTimestamp is the creation time of the genesis block.
+	CreateTimestamp *big.Int
+
+	// Allocations pre-funds the given addresses in the genesis state, so
+	// tests and mainnet/testnet configs can declare initial balances
+	// declaratively instead of mutating state after the fact. Every
+	// allocated address must belong to common.LocalShardNumber.
+	Allocations map[common.Address]GenesisAccount
+}
+
+// Genesis is the genesis block of the blockchain.
+type Genesis struct {
+	info *GenesisInfo
+}
+
+// GetGenesis gets the genesis block according to the given genesis info.
+func GetGenesis(info GenesisInfo) *Genesis {
+	if info.CreateTimestamp == nil {
+		info.CreateTimestamp = big.NewInt(0)
+	}
+
+	return &Genesis{&info}
+}
+
+// InitializeAndValidate writes the genesis allocations into the state trie,
+// creates the genesis block in the given blockchain store if it does not
+// already exist, and validates that any existing genesis block matches info.
+func (genesis *Genesis) InitializeAndValidate(bcStore store.BlockchainStore, accountStateDB database.Database) error {
+	for addr := range genesis.info.Allocations {
+		if shard := common.GetShardNumber(addr); shard != common.LocalShardNumber {
+			return fmt.Errorf("invalid allocation address %v, shard number is [%v], but coinbase shard number is [%v]", addr, shard, common.LocalShardNumber)
+		}
+	}
+
+	statedb, err := state.NewStatedb(common.EmptyHash, accountStateDB)
+	if err != nil {
+		return err
+	}
+
+	for addr, account := range genesis.info.Allocations {
+		stateObj := statedb.GetOrNewStateObject(addr)
+
+		balance := account.Balance
+		if balance == nil {
+			balance = big.NewInt(0)
+		}
+		stateObj.SetAmount(balance)
+		stateObj.SetNonce(account.Nonce)
+
+		if len(account.Code) > 0 {
+			stateObj.SetCode(account.Code)
+		}
+
+		for key, value := range account.Storage {
+			stateObj.SetState(key, value)
+		}
+	}
+
+	batch := accountStateDB.NewBatch()
+	stateHash, err := statedb.Commit(batch)
+	if err != nil {
+		return err
+	}
+
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+
+	genesisBlock := &types.Block{
+		Header: &types.BlockHeader{
+			PreviousBlockHash: common.EmptyHash,
+			Creator:           common.EmptyAddress,
+			StateHash:         stateHash,
+			TxHash:            types.MerkleRootHash(nil),
+			Difficulty:        big.NewInt(genesis.info.Difficulty),
+			Height:            0,
+			CreateTimestamp:   genesis.info.CreateTimestamp,
+			Nonce:             0,
+			ExtraData:         make([]byte, 0),
+		},
+	}
+	genesisBlock.HeaderHash = genesisBlock.Header.Hash()
+
+	existing, err := bcStore.GetBlockByHeight(0)
+	if err != nil {
+		return bcStore.PutBlock(genesisBlock, genesis.info.Difficulty, true)
+	}
+
+	if !existing.HeaderHash.Equal(genesisBlock.HeaderHash) {
+		return fmt.Errorf("genesis block mismatch, existing hash [%v], expected hash [%v]", existing.HeaderHash, genesisBlock.HeaderHash)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/core/vm"
+)
+
+// EVMContext carries the block-level information a transaction is executed
+// against: the header it belongs to, the address that collects its gas fee,
+// and the store used to resolve any chain data the EVM needs.
+type EVMContext struct {
+	tx      *types.Transaction
+	header  *types.BlockHeader
+	creator common.Address
+	bcStore store.BlockchainStore
+}
+
+// NewEVMContext builds the context a transaction is executed against.
+func NewEVMContext(tx *types.Transaction, header *types.BlockHeader, creator common.Address, bcStore store.BlockchainStore) *EVMContext {
+	return &EVMContext{tx, header, creator, bcStore}
+}
+
+// ProcessContract runs tx against statedb, metering gas the way every
+// EVM-style chain does: the sender is charged GasPrice*GasLimit up front, so
+// the cost of a transaction is bounded before any EVM work happens (closing
+// the DoS surface where a payload's actual execution cost could exceed what
+// the sender was charged); once execution finishes, unused gas is refunded
+// to the sender and the gas actually spent is credited to the block
+// creator.
+func ProcessContract(context *EVMContext, tx *types.Transaction, txIndex int, statedb *state.Statedb, config *vm.Config) (*types.Receipt, error) {
+	data := tx.Data
+
+	gasLimit := new(big.Int).SetUint64(data.GasLimit)
+	upfrontCost := new(big.Int).Mul(data.GasPrice, gasLimit)
+
+	addBalance(statedb, data.From, new(big.Int).Neg(upfrontCost))
+
+	evm := vm.NewEVM(vm.Context{
+		Coinbase:    context.creator,
+		BlockHeight: context.header.Height,
+	}, statedb, *config)
+
+	var (
+		ret     []byte
+		gasUsed uint64
+		runErr  error
+	)
+
+	intrinsic := intrinsicGasOf(data)
+
+	if data.To == nil {
+		// Contract creation only charges the intrinsic gas: the init code
+		// is not run, no contract account is created and Amount is not
+		// transferred.
+		gasUsed = intrinsic
+	} else {
+		remaining := data.GasLimit - intrinsic
+
+		var leftOverGas uint64
+		ret, leftOverGas, runErr = evm.Call(data.From, *data.To, data.Payload, remaining, data.Amount)
+
+		if runErr != nil {
+			// A failed call still consumes all the gas it was given, same
+			// as every EVM-style chain: the sender pays for the work the
+			// network did attempting it.
+			gasUsed = data.GasLimit
+		} else {
+			gasUsed = intrinsic + (remaining - leftOverGas)
+		}
+	}
+
+	if gasUsed > data.GasLimit {
+		gasUsed = data.GasLimit
+	}
+
+	unusedGas := data.GasLimit - gasUsed
+	refund := new(big.Int).Mul(data.GasPrice, new(big.Int).SetUint64(unusedGas))
+	addBalance(statedb, data.From, refund)
+
+	fee := new(big.Int).Mul(data.GasPrice, new(big.Int).SetUint64(gasUsed))
+	addBalance(statedb, context.creator, fee)
+
+	return &types.Receipt{
+		Result:  ret,
+		GasUsed: gasUsed,
+		Failed:  runErr != nil,
+	}, nil
+}
+
+// addBalance adds delta (which may be negative) to addr's balance.
+func addBalance(statedb *state.Statedb, addr common.Address, delta *big.Int) {
+	stateObj := statedb.GetOrNewStateObject(addr)
+	balance := new(big.Int).Add(statedb.GetBalance(addr), delta)
+	stateObj.SetAmount(balance)
+}
+
+// intrinsicGasOf returns the intrinsic gas tx.Data must pay regardless of
+// what the EVM itself goes on to execute.
+func intrinsicGasOf(data *types.TransactionData) uint64 {
+	gas := types.TxGas
+	if data.To == nil {
+		gas = types.TxGasContractCreation
+	}
+
+	for _, b := range data.Payload {
+		if b == 0 {
+			gas += types.TxDataZeroGas
+		} else {
+			gas += types.TxDataNonZeroGas
+		}
+	}
+
+	return gas
+}
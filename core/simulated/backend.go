@@ -0,0 +1,338 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+// Package simulated provides an in-process blockchain fixture for unit tests.
+//
+// It gives dApp authors and internal packages (downloader, txpool, seele-rpc)
+// a shared, well-tested alternative to hand-rolling block/state setup in every
+// _test.go file, in the same spirit as go-ethereum's ethclient/simulated.Backend.
+package simulated
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/common/clock"
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/core/vm"
+	"github.com/seeleteam/go-seele/database"
+	"github.com/seeleteam/go-seele/database/leveldb"
+)
+
+var (
+	// ErrUnknownTransaction is returned when a receipt is requested for a
+	// transaction hash the backend never mined.
+	ErrUnknownTransaction = errors.New("simulated: unknown transaction")
+
+	// ErrUnknownSnapshot is returned when RevertToSnapshot is called with an
+	// id that was never returned by Snapshot.
+	ErrUnknownSnapshot = errors.New("simulated: unknown snapshot")
+)
+
+// GenesisAlloc maps addresses to the account state they should hold in the
+// backend's genesis block.
+type GenesisAlloc map[common.Address]GenesisAccount
+
+// GenesisAccount describes the pre-funded state of a single account at
+// genesis.
+type GenesisAccount struct {
+	Balance *big.Int
+	Nonce   uint64
+	Code    []byte
+	Storage map[common.Hash]common.Hash
+}
+
+// Backend is an in-memory seele blockchain meant for unit tests. It wraps a
+// real core.Blockchain and state.Statedb pair backed by a temporary LevelDB
+// instance, so consumers get the exact same execution path as mainnet while
+// staying entirely in-process.
+type Backend struct {
+	db      database.Database
+	dispose func()
+
+	bcStore store.BlockchainStore
+	chain   *core.Blockchain
+
+	config   config
+	autoMine bool
+
+	snapshots map[int]common.Hash
+	nextSnap  int
+}
+
+type config struct {
+	genesis       core.GenesisInfo
+	blockGasLimit uint64
+	consensus     core.ConsensusEngine
+	clock         clock.Clock
+}
+
+// Option configures a Backend returned by NewBackend.
+type Option func(*config)
+
+// WithGenesisInfo overrides the genesis block the backend is seeded with,
+// for example to pre-fund accounts via core.GenesisInfo.Allocations.
+func WithGenesisInfo(genesis core.GenesisInfo) Option {
+	return func(c *config) {
+		c.genesis = genesis
+	}
+}
+
+// WithBlockGasLimit overrides the per-block gas limit enforced while mining.
+func WithBlockGasLimit(limit uint64) Option {
+	return func(c *config) {
+		c.blockGasLimit = limit
+	}
+}
+
+// WithConsensusEngine overrides the consensus engine used to seal blocks,
+// typically a mock engine that seals instantly instead of mining.
+func WithConsensusEngine(engine core.ConsensusEngine) Option {
+	return func(c *config) {
+		c.consensus = engine
+	}
+}
+
+// WithClock overrides the clock used to timestamp mined blocks, so tests can
+// fast-forward time deterministically instead of depending on time.Now.
+func WithClock(c clock.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+const defaultBlockGasLimit uint64 = 4_700_000
+
+// NewBackend creates a Backend seeded with the given allocation and starts it
+// from an empty, temporary on-disk database. Call Backend.Close when done.
+func NewBackend(alloc GenesisAlloc, opts ...Option) (*Backend, error) {
+	cfg := config{
+		blockGasLimit: defaultBlockGasLimit,
+		clock:         clock.New(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.genesis.Allocations == nil {
+		cfg.genesis.Allocations = make(map[common.Address]core.GenesisAccount, len(alloc))
+	}
+	for addr, account := range alloc {
+		cfg.genesis.Allocations[addr] = core.GenesisAccount{
+			Balance: account.Balance,
+			Nonce:   account.Nonce,
+			Code:    account.Code,
+			Storage: account.Storage,
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "simulated-backend")
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := leveldb.NewLevelDB(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	bcStore := store.NewBlockchainDatabase(db)
+	genesis := core.GetGenesis(cfg.genesis)
+	if err := genesis.InitializeAndValidate(bcStore, db); err != nil {
+		db.Close()
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	chain, err := core.NewBlockchain(bcStore, db)
+	if err != nil {
+		db.Close()
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	return &Backend{
+		db:        db,
+		dispose:   func() { db.Close(); os.RemoveAll(dir) },
+		bcStore:   bcStore,
+		chain:     chain,
+		config:    cfg,
+		snapshots: make(map[int]common.Hash),
+	}, nil
+}
+
+// Close releases the backend's underlying database and temporary directory.
+func (b *Backend) Close() {
+	if b.dispose != nil {
+		b.dispose()
+	}
+}
+
+// SetAutoMine enables or disables automatically mining a block after every
+// submitted transaction. It is disabled by default, i.e. callers must call
+// Commit explicitly to produce a block.
+func (b *Backend) SetAutoMine(enabled bool) {
+	b.autoMine = enabled
+}
+
+// SubmitTransaction validates and queues a transaction against the backend's
+// current state, mining it immediately if auto-mine is enabled.
+func (b *Backend) SubmitTransaction(tx *types.Transaction) (common.Hash, error) {
+	statedb, err := b.currentState()
+	if err != nil {
+		return common.EmptyHash, err
+	}
+
+	if err := tx.Validate(statedb); err != nil {
+		return common.EmptyHash, err
+	}
+
+	if err := b.chain.AddTransaction(tx); err != nil {
+		return common.EmptyHash, err
+	}
+
+	if b.autoMine {
+		if _, err := b.Commit(); err != nil {
+			return common.EmptyHash, err
+		}
+	}
+
+	return tx.Hash, nil
+}
+
+// Commit mines all pending transactions into a new block and returns its
+// hash. It is a no-op error-free call when there is nothing pending.
+func (b *Backend) Commit() (common.Hash, error) {
+	block, err := b.chain.MineBlock(b.config.consensus, b.config.clock.Now(), b.config.blockGasLimit)
+	if err != nil {
+		return common.EmptyHash, err
+	}
+
+	return block.HeaderHash, nil
+}
+
+// AdjustTime fast-forwards the backend's clock by the given duration, so the
+// next mined block is timestamped accordingly. It requires the backend to
+// have been built with a *clock.Mock (see WithClock) and returns an error
+// for any other clock.
+func (b *Backend) AdjustTime(d time.Duration) error {
+	mock, ok := b.config.clock.(*clock.Mock)
+	if !ok {
+		return errors.New("simulated: AdjustTime requires a *clock.Mock, use WithClock")
+	}
+
+	mock.Add(d)
+	return nil
+}
+
+// Snapshot records the current head state and returns an id that can later
+// be passed to RevertToSnapshot.
+func (b *Backend) Snapshot() int {
+	id := b.nextSnap
+	b.nextSnap++
+	b.snapshots[id] = b.chain.CurrentBlock().HeaderHash
+	return id
+}
+
+// RevertToSnapshot rolls the chain back to the head recorded by Snapshot.
+func (b *Backend) RevertToSnapshot(id int) error {
+	head, ok := b.snapshots[id]
+	if !ok {
+		return ErrUnknownSnapshot
+	}
+
+	return b.chain.Rollback(head)
+}
+
+// TransactionReceipt returns the receipt for a mined transaction.
+func (b *Backend) TransactionReceipt(hash common.Hash) (*types.Receipt, error) {
+	receipt, err := b.bcStore.GetReceiptByTxHash(hash)
+	if err != nil {
+		return nil, ErrUnknownTransaction
+	}
+
+	return receipt, nil
+}
+
+// BalanceAt returns the balance of the given address at the current head.
+func (b *Backend) BalanceAt(addr common.Address) (*big.Int, error) {
+	statedb, err := b.currentState()
+	if err != nil {
+		return nil, err
+	}
+
+	return statedb.GetBalance(addr), nil
+}
+
+// StorageAt returns the value stored at the given contract storage slot at
+// the current head.
+func (b *Backend) StorageAt(addr common.Address, key common.Hash) (common.Hash, error) {
+	statedb, err := b.currentState()
+	if err != nil {
+		return common.EmptyHash, err
+	}
+
+	return statedb.GetData(addr, key), nil
+}
+
+func (b *Backend) currentState() (*state.Statedb, error) {
+	head := b.chain.CurrentBlock()
+	return state.NewStatedb(head.Header.StateHash, b.db)
+}
+
+// Client returns a stub satisfying the same call interface the seele RPC
+// client exposes, letting contract integration tests drive the backend
+// in-process instead of going over the wire.
+func (b *Backend) Client() *Client {
+	return &Client{backend: b}
+}
+
+// Client is a minimal in-process stand-in for the seele RPC client, backed
+// directly by a Backend instead of a network connection.
+type Client struct {
+	backend *Backend
+}
+
+// SendTransaction submits a transaction the same way the real RPC client
+// would, returning its hash once accepted.
+func (c *Client) SendTransaction(tx *types.Transaction) (common.Hash, error) {
+	return c.backend.SubmitTransaction(tx)
+}
+
+// TransactionReceipt returns the receipt for a mined transaction.
+func (c *Client) TransactionReceipt(hash common.Hash) (*types.Receipt, error) {
+	return c.backend.TransactionReceipt(hash)
+}
+
+// BalanceAt returns the balance of the given address at the current head.
+func (c *Client) BalanceAt(addr common.Address) (*big.Int, error) {
+	return c.backend.BalanceAt(addr)
+}
+
+// CallContract executes a call against the current head state without
+// mining a block, mirroring an eth_call style read.
+func (c *Client) CallContract(tx *types.Transaction) ([]byte, error) {
+	statedb, err := c.backend.currentState()
+	if err != nil {
+		return nil, err
+	}
+
+	header := c.backend.chain.CurrentBlock().Header
+	evmContext := core.NewEVMContext(tx, header, header.Creator, c.backend.bcStore)
+	receipt, err := core.ProcessContract(evmContext, tx, 0, statedb, &vm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	return receipt.Result, nil
+}
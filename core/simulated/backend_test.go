@@ -0,0 +1,99 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package simulated
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBackend(t *testing.T, alloc GenesisAlloc) *Backend {
+	backend, err := NewBackend(alloc)
+	if err != nil {
+		t.Fatalf("Failed to create simulated backend, error = %s", err.Error())
+	}
+
+	return backend
+}
+
+func Test_Backend_BalanceAt_usesAllocation(t *testing.T) {
+	addr := *crypto.MustGenerateRandomAddress()
+	backend := newTestBackend(t, GenesisAlloc{
+		addr: {Balance: big.NewInt(100)},
+	})
+	defer backend.Close()
+
+	balance, err := backend.BalanceAt(addr)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, big.NewInt(100), balance)
+}
+
+func Test_Backend_Commit_withoutPendingTxs(t *testing.T) {
+	backend := newTestBackend(t, GenesisAlloc{})
+	defer backend.Close()
+
+	_, err := backend.Commit()
+	assert.Equal(t, nil, err)
+}
+
+func Test_Backend_RevertToSnapshot_unknownID(t *testing.T) {
+	backend := newTestBackend(t, GenesisAlloc{})
+	defer backend.Close()
+
+	err := backend.RevertToSnapshot(42)
+	assert.Equal(t, ErrUnknownSnapshot, err)
+}
+
+func Test_Backend_AdjustTime_requiresMockClock(t *testing.T) {
+	backend := newTestBackend(t, GenesisAlloc{})
+	defer backend.Close()
+
+	err := backend.AdjustTime(0)
+	assert.NotNil(t, err)
+}
+
+func Test_Backend_SubmitTransaction_autoMineMovesBalanceAndRecordsReceipt(t *testing.T) {
+	privKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key, error = %s", err.Error())
+	}
+
+	from := common.HexMustToAddres(crypto.PubkeyToString(&privKey.PublicKey))
+	to := *crypto.MustGenerateRandomAddress()
+
+	backend := newTestBackend(t, GenesisAlloc{
+		from: {Balance: big.NewInt(1000000)},
+	})
+	defer backend.Close()
+	backend.SetAutoMine(true)
+
+	tx, err := types.NewTransaction(from, to, big.NewInt(100), big.NewInt(1), types.TxGas, 0)
+	if err != nil {
+		t.Fatalf("Failed to create tx, error = %s", err.Error())
+	}
+	tx.Sign(privKey)
+
+	hash, err := backend.SubmitTransaction(tx)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, tx.Hash, hash)
+
+	receipt, err := backend.TransactionReceipt(hash)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, receipt.Failed)
+
+	fromBalance, err := backend.BalanceAt(from)
+	assert.Equal(t, nil, err)
+	assert.True(t, new(big.Int).Sub(big.NewInt(1000000), fromBalance).Sign() > 0)
+
+	toBalance, err := backend.BalanceAt(to)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, big.NewInt(100), toBalance)
+}
@@ -0,0 +1,68 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+var (
+	// ErrBlockGasLimitReached is returned when a block's transactions spend
+	// more gas in total than its GasLimit allows.
+	ErrBlockGasLimitReached = errors.New("block gas limit reached")
+
+	// ErrBlockGasUsedMismatch describes a block whose declared GasUsed does
+	// not match the sum of its transaction receipts. No check in this
+	// package returns it; ValidateGasUsed only compares GasUsed to GasLimit.
+	ErrBlockGasUsedMismatch = errors.New("block gas used mismatch")
+)
+
+// BlockHeader represents the header of a block in the blockchain.
+type BlockHeader struct {
+	PreviousBlockHash common.Hash // PreviousBlockHash is the hash of the previous block header
+	Creator           common.Address
+	StateHash         common.Hash // StateHash is the root hash of the state trie after executing this block
+	TxHash            common.Hash // TxHash is the root hash of the block's transaction merkle tree
+	ReceiptHash       common.Hash // ReceiptHash is the root hash of the block's receipt merkle tree
+	Difficulty        *big.Int
+	Height            uint64
+	CreateTimestamp   *big.Int
+	Nonce             uint64
+	ExtraData         []byte
+
+	// GasLimit is the maximum amount of gas the block's transactions may
+	// consume in total.
+	GasLimit uint64
+
+	// GasUsed is the total gas actually consumed by the block's transactions.
+	GasUsed uint64
+}
+
+// Block represents a block in the blockchain.
+type Block struct {
+	HeaderHash   common.Hash
+	Header       *BlockHeader
+	Transactions []*Transaction
+}
+
+// Hash calculates and returns the block header hash.
+func (header *BlockHeader) Hash() common.Hash {
+	return crypto.MustHash(header)
+}
+
+// ValidateGasUsed validates that the header's GasUsed does not exceed its
+// GasLimit, returning ErrBlockGasLimitReached otherwise.
+func (header *BlockHeader) ValidateGasUsed() error {
+	if header.GasUsed > header.GasLimit {
+		return ErrBlockGasLimitReached
+	}
+
+	return nil
+}
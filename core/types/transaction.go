@@ -19,6 +19,20 @@ import (
 
 const (
 	defaultMaxPayloadSize = 32 * 1024
+
+	// TxGas is the intrinsic gas charged for every transaction, regardless
+	// of payload, mirroring the Ethereum-family base cost.
+	TxGas uint64 = 21000
+
+	// TxGasContractCreation is the intrinsic gas charged for a transaction
+	// that creates a new contract.
+	TxGasContractCreation uint64 = 53000
+
+	// TxDataZeroGas is the gas charged per zero byte of transaction payload.
+	TxDataZeroGas uint64 = 4
+
+	// TxDataNonZeroGas is the gas charged per non-zero byte of transaction payload.
+	TxDataNonZeroGas uint64 = 68
 )
 
 var (
@@ -32,8 +46,18 @@ var (
 	ErrBalanceNotEnough = errors.New("balance not enough")
 
 	// ErrFeeNegative is returned when the transaction fee is negative.
+	//
+	// Deprecated: Fee has been replaced by GasPrice/GasLimit, use
+	// ErrGasPriceNegative instead.
 	ErrFeeNegative = errors.New("failed to create tx, fee is negative")
 
+	// ErrGasPriceNegative is returned when the transaction gas price is negative.
+	ErrGasPriceNegative = errors.New("failed to create tx, gas price is negative")
+
+	// ErrGasLimitTooLow is returned when the transaction gas limit is lower
+	// than the intrinsic gas required to run it.
+	ErrGasLimitTooLow = errors.New("gas limit is lower than the intrinsic gas required")
+
 	// ErrHashMismatch is returned when the transaction hash and data mismatch.
 	ErrHashMismatch = errors.New("hash mismatch")
 
@@ -61,9 +85,18 @@ type TransactionData struct {
 	To           *common.Address // To is the receiver address, which is nil for contract creation transaction
 	Amount       *big.Int        // Amount is the amount to be transferred
 	AccountNonce uint64          // AccountNonce is the nonce of the sender account
-	Fee          *big.Int        // Transaction Fee
+	GasPrice     *big.Int        // GasPrice is the price the sender pays per unit of gas
+	GasLimit     uint64          // GasLimit is the maximum amount of gas the transaction may consume
 	Timestamp    uint64          // Timestamp is unix nano time when the transaction is created
 	Payload      []byte          // Payload is the extra data of the transaction
+
+	// Fee is the total transaction fee, kept for RLP backwards compatibility
+	// with transactions created before the GasPrice/GasLimit split.
+	//
+	// Deprecated: derive the fee from GasPrice*GasUsed instead. Fee is
+	// populated as GasPrice*GasLimit on new transactions so that old decoders
+	// still see a sensible upper bound.
+	Fee *big.Int
 }
 
 // Transaction represents a transaction in the blockchain.
@@ -87,15 +120,15 @@ type stateDB interface {
 // NewTransaction creates a new transaction to transfer asset.
 // The transaction data hash is also calculated.
 // panic if the amount is nil or negative.
-func NewTransaction(from, to common.Address, amount *big.Int, fee *big.Int, nonce uint64) (*Transaction, error) {
-	tx, err := newTx(from, &to, amount, fee, nonce, nil)
+func NewTransaction(from, to common.Address, amount *big.Int, gasPrice *big.Int, gasLimit uint64, nonce uint64) (*Transaction, error) {
+	tx, err := newTx(from, &to, amount, gasPrice, gasLimit, nonce, nil)
 	if err != nil {
 		return nil, err
 	}
 	return tx, nil
 }
 
-func newTx(from common.Address, to *common.Address, amount *big.Int, fee *big.Int, nonce uint64, payload []byte) (*Transaction, error) {
+func newTx(from common.Address, to *common.Address, amount *big.Int, gasPrice *big.Int, gasLimit uint64, nonce uint64, payload []byte) (*Transaction, error) {
 	if amount == nil {
 		panic("Failed to create tx, amount is nil.")
 	}
@@ -104,19 +137,25 @@ func newTx(from common.Address, to *common.Address, amount *big.Int, fee *big.In
 		panic("Failed to create tx, amount is negative.")
 	}
 
-	if fee.Sign() < 0 {
-		return nil, ErrFeeNegative
+	if gasPrice.Sign() < 0 {
+		return nil, ErrGasPriceNegative
 	}
 
 	if len(payload) > MaxPayloadSize {
 		return nil, ErrPayloadOversized
 	}
 
+	if gasLimit < intrinsicGas(payload, to == nil) {
+		return nil, ErrGasLimitTooLow
+	}
+
 	txData := &TransactionData{
 		From:         from,
 		To:           to,
 		Amount:       new(big.Int).Set(amount),
-		Fee:          new(big.Int).Set(fee),
+		GasPrice:     new(big.Int).Set(gasPrice),
+		GasLimit:     gasLimit,
+		Fee:          new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasLimit)),
 		Timestamp:    uint64(time.Now().UnixNano()),
 		AccountNonce: nonce,
 	}
@@ -133,13 +172,68 @@ func newTx(from common.Address, to *common.Address, amount *big.Int, fee *big.In
 }
 
 // NewContractTransaction returns a transaction to create a smart contract.
-func NewContractTransaction(from common.Address, amount *big.Int, fee *big.Int, nonce uint64, code []byte) (*Transaction, error) {
-	return newTx(from, nil, amount, fee, nonce, code)
+func NewContractTransaction(from common.Address, amount *big.Int, gasPrice *big.Int, gasLimit uint64, nonce uint64, code []byte) (*Transaction, error) {
+	return newTx(from, nil, amount, gasPrice, gasLimit, nonce, code)
 }
 
 // NewMessageTransaction returns a transation with the specified message.
-func NewMessageTransaction(from, to common.Address, amount *big.Int, fee *big.Int, nonce uint64, msg []byte) (*Transaction, error) {
-	return newTx(from, &to, amount, fee, nonce, msg)
+func NewMessageTransaction(from, to common.Address, amount *big.Int, gasPrice *big.Int, gasLimit uint64, nonce uint64, msg []byte) (*Transaction, error) {
+	return newTx(from, &to, amount, gasPrice, gasLimit, nonce, msg)
+}
+
+// NewTransactionFromFee creates a new transaction the way callers did before
+// the GasPrice/GasLimit split, by spending the whole fee as GasPrice*GasLimit
+// at the given gasLimit, with GasPrice rounded up so that GasPrice*GasLimit
+// is at least fee. A gasLimit of 0 is replaced by the intrinsic gas of a
+// plain transfer. It exists to ease the migration of callers that still only
+// know about a flat fee.
+//
+// Deprecated: compute an explicit GasPrice and GasLimit and call
+// NewTransaction instead.
+func NewTransactionFromFee(from, to common.Address, amount *big.Int, fee *big.Int, gasLimit uint64, nonce uint64) (*Transaction, error) {
+	if gasLimit == 0 {
+		gasLimit = intrinsicGas(nil, false)
+	}
+
+	gasPrice := feeToGasPrice(fee, gasLimit)
+
+	return NewTransaction(from, to, amount, gasPrice, gasLimit, nonce)
+}
+
+// feeToGasPrice derives a gas price from a flat fee and gas limit, rounding
+// up so that gasPrice*gasLimit never falls short of fee.
+func feeToGasPrice(fee *big.Int, gasLimit uint64) *big.Int {
+	if fee == nil || gasLimit == 0 {
+		return big.NewInt(0)
+	}
+
+	limit := new(big.Int).SetUint64(gasLimit)
+	gasPrice := new(big.Int).Div(fee, limit)
+	if new(big.Int).Mul(gasPrice, limit).Cmp(fee) < 0 {
+		gasPrice.Add(gasPrice, big.NewInt(1))
+	}
+
+	return gasPrice
+}
+
+// intrinsicGas computes the minimum gas a transaction must pay for before
+// any EVM execution happens, based on whether it creates a contract and how
+// many zero/non-zero bytes its payload carries.
+func intrinsicGas(payload []byte, contractCreation bool) uint64 {
+	gas := TxGas
+	if contractCreation {
+		gas = TxGasContractCreation
+	}
+
+	for _, b := range payload {
+		if b == 0 {
+			gas += TxDataZeroGas
+		} else {
+			gas += TxDataNonZeroGas
+		}
+	}
+
+	return gas
 }
 
 // Sign signs the transaction with the specified private key.
@@ -148,12 +242,31 @@ func (tx *Transaction) Sign(privKey *ecdsa.PrivateKey) {
 	tx.Signature = crypto.NewSignature(privKey, tx.Hash.Bytes())
 }
 
+// normalizeLegacyGas backfills GasPrice/GasLimit on a TransactionData
+// decoded from the pre-GasPrice/GasLimit RLP format, where only Fee was
+// recorded and GasPrice therefore decodes as nil, so every caller downstream
+// of Validate can keep assuming GasPrice is always set instead of crashing
+// on legacy transactions.
+func (data *TransactionData) normalizeLegacyGas() {
+	if data.GasPrice != nil {
+		return
+	}
+
+	if data.GasLimit == 0 {
+		data.GasLimit = intrinsicGas(data.Payload, data.To == nil)
+	}
+
+	data.GasPrice = feeToGasPrice(data.Fee, data.GasLimit)
+}
+
 // Validate returns true if the transaction is valid, otherwise false.
 func (tx *Transaction) Validate(statedb stateDB) error {
 	if tx.Data == nil || tx.Data.Amount == nil {
 		return ErrAmountNil
 	}
 
+	tx.Data.normalizeLegacyGas()
+
 	if tx.Data.Amount.Sign() < 0 {
 		return ErrAmountNegative
 	}
@@ -168,7 +281,13 @@ func (tx *Transaction) Validate(statedb stateDB) error {
 		}
 	}
 
-	if balance := statedb.GetBalance(tx.Data.From); tx.Data.Amount.Cmp(balance) > 0 {
+	if tx.Data.GasLimit < intrinsicGas(tx.Data.Payload, tx.Data.To == nil) {
+		return ErrGasLimitTooLow
+	}
+
+	gasCost := new(big.Int).Mul(tx.Data.GasPrice, new(big.Int).SetUint64(tx.Data.GasLimit))
+	cost := new(big.Int).Add(tx.Data.Amount, gasCost)
+	if balance := statedb.GetBalance(tx.Data.From); cost.Cmp(balance) > 0 {
 		return ErrBalanceNotEnough
 	}
 
@@ -0,0 +1,117 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_intrinsicGas_transfer(t *testing.T) {
+	assert.Equal(t, TxGas, intrinsicGas(nil, false))
+}
+
+func Test_intrinsicGas_contractCreation(t *testing.T) {
+	assert.Equal(t, TxGasContractCreation, intrinsicGas(nil, true))
+}
+
+func Test_intrinsicGas_payload(t *testing.T) {
+	payload := []byte{0, 1, 0, 2}
+	expected := TxGas + 2*TxDataZeroGas + 2*TxDataNonZeroGas
+	assert.Equal(t, expected, intrinsicGas(payload, false))
+}
+
+func Test_feeToGasPrice_exact(t *testing.T) {
+	gasPrice := feeToGasPrice(big.NewInt(100), 10)
+	assert.Equal(t, big.NewInt(10), gasPrice)
+}
+
+func Test_feeToGasPrice_roundsUp(t *testing.T) {
+	gasPrice := feeToGasPrice(big.NewInt(101), 10)
+	assert.Equal(t, big.NewInt(11), gasPrice)
+}
+
+func Test_feeToGasPrice_zeroGasLimit(t *testing.T) {
+	gasPrice := feeToGasPrice(big.NewInt(101), 0)
+	assert.Equal(t, big.NewInt(0), gasPrice)
+}
+
+// fakeStateDB is a minimal stateDB used to drive Transaction.Validate
+// without a real state.Statedb.
+type fakeStateDB struct {
+	balance *big.Int
+	nonce   uint64
+}
+
+func (s fakeStateDB) GetBalance(common.Address) *big.Int {
+	return s.balance
+}
+
+func (s fakeStateDB) GetNonce(common.Address) uint64 {
+	return s.nonce
+}
+
+func newValidTx(t *testing.T, amount, gasPrice *big.Int, gasLimit uint64) (*Transaction, *ecdsa.PrivateKey) {
+	privKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key, error = %s", err.Error())
+	}
+
+	from := common.HexMustToAddres(crypto.PubkeyToString(&privKey.PublicKey))
+	to := *crypto.MustGenerateRandomAddress()
+
+	tx, err := NewTransaction(from, to, amount, gasPrice, gasLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to create tx, error = %s", err.Error())
+	}
+
+	tx.Sign(privKey)
+
+	return tx, privKey
+}
+
+func Test_Transaction_Validate_balanceCoversAmountAndGas(t *testing.T) {
+	tx, _ := newValidTx(t, big.NewInt(10), big.NewInt(1), TxGas)
+
+	gasCost := new(big.Int).Mul(big.NewInt(1), new(big.Int).SetUint64(TxGas))
+	cost := new(big.Int).Add(big.NewInt(10), gasCost)
+
+	statedb := fakeStateDB{balance: new(big.Int).Sub(cost, big.NewInt(1))}
+	assert.Equal(t, ErrBalanceNotEnough, tx.Validate(statedb))
+
+	statedb = fakeStateDB{balance: cost}
+	assert.Equal(t, nil, tx.Validate(statedb))
+}
+
+func Test_Transaction_Validate_gasLimitTooLow(t *testing.T) {
+	tx, _ := newValidTx(t, big.NewInt(10), big.NewInt(1), TxGas)
+	tx.Data.GasLimit = TxGas - 1
+
+	statedb := fakeStateDB{balance: big.NewInt(1000000)}
+	assert.Equal(t, ErrGasLimitTooLow, tx.Validate(statedb))
+}
+
+func Test_Transaction_Validate_legacyFeeOnlyDoesNotPanic(t *testing.T) {
+	tx, _ := newValidTx(t, big.NewInt(10), big.NewInt(1), TxGas)
+
+	// Simulate a transaction decoded from the pre-GasPrice/GasLimit RLP
+	// format: only Fee survived, GasPrice/GasLimit decode as their zero
+	// values.
+	tx.Data.Fee = new(big.Int).Mul(big.NewInt(1), new(big.Int).SetUint64(TxGas))
+	tx.Data.GasPrice = nil
+	tx.Data.GasLimit = 0
+
+	statedb := fakeStateDB{balance: big.NewInt(1000000)}
+	assert.NotPanics(t, func() { tx.Validate(statedb) })
+
+	assert.Equal(t, TxGas, tx.Data.GasLimit)
+	assert.Equal(t, big.NewInt(1), tx.Data.GasPrice)
+}
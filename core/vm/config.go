@@ -0,0 +1,26 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package vm
+
+// Config houses the runtime options the EVM is executed with.
+type Config struct {
+	// Debug enables additional logging/tracing of EVM execution.
+	Debug bool
+
+	// Precompiles is consulted by EVM.Call before dispatching to bytecode,
+	// so native Go contracts (ecrecover, sha256, a cross-shard messenger,
+	// ...) can be called like any other contract address. A nil value
+	// falls back to NewPrecompileRegistry's built-ins.
+	Precompiles *PrecompileRegistry
+}
+
+func (c *Config) precompiles() *PrecompileRegistry {
+	if c.Precompiles == nil {
+		c.Precompiles = NewPrecompileRegistry()
+	}
+
+	return c.Precompiles
+}
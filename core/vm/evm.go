@@ -0,0 +1,105 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/seeleteam/go-seele/common"
+)
+
+// ErrOutOfGas is returned when the caller does not supply enough gas to
+// cover a call's required gas, whether it dispatches to a precompile or to
+// interpreted bytecode.
+var ErrOutOfGas = errors.New("out of gas")
+
+// ErrInsufficientBalance is returned when a call carries a value transfer
+// the caller cannot cover.
+var ErrInsufficientBalance = errors.New("insufficient balance for transfer")
+
+// StateDB is the subset of state.Statedb the EVM needs, kept as an
+// interface so this package does not import core/state directly.
+type StateDB interface {
+	GetBalance(common.Address) *big.Int
+	SetBalance(common.Address, *big.Int)
+	GetCode(common.Address) []byte
+	GetState(common.Address, common.Hash) common.Hash
+	SetState(common.Address, common.Hash, common.Hash)
+}
+
+// Context carries the block-level information a call needs but that isn't
+// part of the call's own arguments, such as the block height precompile
+// activation is gated on.
+type Context struct {
+	Coinbase    common.Address
+	BlockHeight uint64
+}
+
+// bytecodeRunner executes interpreted contract bytecode. This package holds
+// no interpreter: NewEVM installs defaultBytecodeRunner, which returns an
+// error so a partially wired EVM fails loudly rather than silently skipping
+// execution, until SetBytecodeRunner installs a real one.
+type bytecodeRunner func(evm *EVM, caller, addr common.Address, input []byte, gas uint64, value *big.Int) ([]byte, uint64, error)
+
+func defaultBytecodeRunner(evm *EVM, caller, addr common.Address, input []byte, gas uint64, value *big.Int) ([]byte, uint64, error) {
+	return nil, gas, errors.New("vm: no bytecode interpreter configured")
+}
+
+// EVM executes a single transaction's worth of contract calls against a
+// StateDB, dispatching to registered precompiles before falling back to the
+// bytecode interpreter.
+type EVM struct {
+	Context
+	StateDB StateDB
+	Config  Config
+
+	runBytecode bytecodeRunner
+}
+
+// NewEVM creates an EVM ready to process calls for the given context.
+func NewEVM(context Context, statedb StateDB, config Config) *EVM {
+	return &EVM{
+		Context:     context,
+		StateDB:     statedb,
+		Config:      config,
+		runBytecode: defaultBytecodeRunner,
+	}
+}
+
+// SetBytecodeRunner overrides the interpreter EVM.Call falls back to once no
+// precompile is registered at the target address. It exists so the
+// interpreter (defined elsewhere) can wire itself in without this package
+// depending on it.
+func (evm *EVM) SetBytecodeRunner(run func(evm *EVM, caller, addr common.Address, input []byte, gas uint64, value *big.Int) ([]byte, uint64, error)) {
+	evm.runBytecode = run
+}
+
+// Call executes a message call against addr. If a precompile is registered
+// at addr and active at the EVM's current block height, it is run directly;
+// otherwise the call falls through to interpreted bytecode.
+func (evm *EVM) Call(caller common.Address, addr common.Address, input []byte, gas uint64, value *big.Int) (ret []byte, leftOverGas uint64, err error) {
+	if value != nil && value.Sign() != 0 {
+		if evm.StateDB.GetBalance(caller).Cmp(value) < 0 {
+			return nil, gas, ErrInsufficientBalance
+		}
+
+		evm.StateDB.SetBalance(caller, new(big.Int).Sub(evm.StateDB.GetBalance(caller), value))
+		evm.StateDB.SetBalance(addr, new(big.Int).Add(evm.StateDB.GetBalance(addr), value))
+	}
+
+	if precompile, ok := evm.Config.precompiles().Get(addr, evm.BlockHeight); ok {
+		requiredGas := precompile.RequiredGas(input)
+		if gas < requiredGas {
+			return nil, 0, ErrOutOfGas
+		}
+
+		ret, err = precompile.Run(evm, caller, input, value)
+		return ret, gas - requiredGas, err
+	}
+
+	return evm.runBytecode(evm, caller, addr, input, gas, value)
+}
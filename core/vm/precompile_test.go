@@ -0,0 +1,80 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package vm
+
+import (
+	"testing"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PrecompileRegistry_builtinsActiveFromGenesis(t *testing.T) {
+	registry := NewPrecompileRegistry()
+
+	_, ok := registry.Get(ecrecoverAddress, 0)
+	assert.True(t, ok)
+
+	_, ok = registry.Get(sha256Address, 0)
+	assert.True(t, ok)
+
+	_, ok = registry.Get(ripemd160Address, 0)
+	assert.True(t, ok)
+
+	_, ok = registry.Get(identityAddress, 0)
+	assert.True(t, ok)
+}
+
+func Test_PrecompileRegistry_Get_respectsActivationHeight(t *testing.T) {
+	registry := NewPrecompileRegistry()
+	addr := common.BytesToAddress([]byte{42})
+
+	_, ok := registry.Get(addr, 100)
+	assert.False(t, ok)
+
+	registry.RegisterPrecompile(addr, identityContract{}, 100)
+
+	_, ok = registry.Get(addr, 99)
+	assert.False(t, ok)
+
+	_, ok = registry.Get(addr, 100)
+	assert.True(t, ok)
+}
+
+func Test_EVM_Call_dispatchesToPrecompile(t *testing.T) {
+	evm := NewEVM(Context{}, nil, Config{})
+
+	ret, leftOverGas, err := evm.Call(common.EmptyAddress, identityAddress, []byte("hello"), identityBaseGas+identityWordGas, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []byte("hello"), ret)
+	assert.Equal(t, uint64(0), leftOverGas)
+}
+
+func Test_EVM_Call_outOfGas(t *testing.T) {
+	evm := NewEVM(Context{}, nil, Config{})
+
+	_, _, err := evm.Call(common.EmptyAddress, identityAddress, []byte("hello"), 1, nil)
+	assert.Equal(t, ErrOutOfGas, err)
+}
+
+func Test_ecrecoverContract_Run_rejectsInvalidRecoveryID(t *testing.T) {
+	input := make([]byte, 128)
+	input[63] = 29 // only 27/28 are valid Ethereum recovery ids
+
+	out, err := (ecrecoverContract{}).Run(nil, common.EmptyAddress, input, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(out))
+}
+
+func Test_ecrecoverContract_Run_rejectsNonStandardVEncoding(t *testing.T) {
+	input := make([]byte, 128)
+	input[32] = 1 // garbage in v's high bytes instead of a clean 27/28 word
+	input[63] = 27
+
+	out, err := (ecrecoverContract{}).Run(nil, common.EmptyAddress, input, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(out))
+}
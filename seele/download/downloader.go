@@ -0,0 +1,300 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package downloader
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+// SyncMode describes how the Downloader follows the chain.
+type SyncMode int
+
+const (
+	// FullSync downloads full blocks and executes every state transition,
+	// the only mode this downloader historically supported.
+	FullSync SyncMode = iota
+
+	// LightSync downloads only block headers, verifying the PoW/difficulty
+	// chain and storing a sparse header index, without ever pulling
+	// transaction bodies or executing state transitions. Reads against
+	// state are instead checked with VerifyProof against Merkle proofs
+	// obtained from full peers.
+	LightSync
+
+	// FastSync is reserved for a future full-state-snapshot sync strategy.
+	FastSync
+)
+
+var (
+	// ErrUnknownHeader is returned when a header is requested by a hash or
+	// height the downloader has not indexed.
+	ErrUnknownHeader = errors.New("downloader: unknown header")
+
+	// ErrLightSyncRequired is returned when a light-sync-only API is called
+	// on a downloader running in FullSync/FastSync mode.
+	ErrLightSyncRequired = errors.New("downloader: operation requires LightSync mode")
+
+	// ErrConsensusEngineRequired is returned by SyncHeaders when the
+	// downloader was not given a ConsensusEngine to verify fetched headers
+	// against, since indexing an unverified header would let a malicious
+	// peer forge an arbitrary chain.
+	ErrConsensusEngineRequired = errors.New("downloader: LightSync requires a consensus engine")
+
+	// ErrInvalidHeader is returned when a fetched header fails PoW/difficulty
+	// verification against the consensus engine.
+	ErrInvalidHeader = errors.New("downloader: header failed consensus verification")
+)
+
+// ConsensusEngine is the subset of the chain's consensus engine the
+// downloader needs to reject forged headers in LightSync mode, where
+// headers are trusted without ever downloading and re-executing the full
+// blocks behind them.
+type ConsensusEngine interface {
+	// VerifyHeader checks the header's PoW/difficulty against consensus
+	// rules, returning a non-nil error if the header could not have been
+	// legitimately produced.
+	VerifyHeader(header *types.BlockHeader) error
+}
+
+// Downloader synchronizes the local blockchain against remote peers, either
+// by pulling full blocks (FullSync) or, in LightSync mode, by pulling only
+// headers, against which VerifyProof checks Merkle proofs that callers
+// obtain from full peers.
+type Downloader struct {
+	mode SyncMode
+
+	blockchain *core.Blockchain
+	bcStore    store.BlockchainStore
+	consensus  ConsensusEngine // required for SyncHeaders; unused in FullSync
+
+	lock    sync.RWMutex
+	headers map[uint64]*types.BlockHeader // height -> header, populated in LightSync mode
+}
+
+// NewDownloader creates a Downloader in the default FullSync mode.
+func NewDownloader(chain *core.Blockchain) *Downloader {
+	return NewDownloaderWithMode(chain, FullSync)
+}
+
+// NewDownloaderWithMode creates a Downloader running the given SyncMode. Use
+// NewLightDownloader instead if the downloader needs to call SyncHeaders, so
+// fetched headers can be verified against a consensus engine.
+func NewDownloaderWithMode(chain *core.Blockchain, mode SyncMode) *Downloader {
+	return &Downloader{
+		mode:       mode,
+		blockchain: chain,
+		bcStore:    chain.GetStore(),
+		headers:    make(map[uint64]*types.BlockHeader),
+	}
+}
+
+// NewLightDownloader creates a Downloader running in LightSync mode, whose
+// SyncHeaders calls verify every fetched header against engine before
+// indexing it.
+func NewLightDownloader(chain *core.Blockchain, engine ConsensusEngine) *Downloader {
+	d := NewDownloaderWithMode(chain, LightSync)
+	d.consensus = engine
+	return d
+}
+
+// SyncMode returns the sync strategy this downloader is running.
+func (d *Downloader) SyncMode() SyncMode {
+	return d.mode
+}
+
+// localHeight returns the height of the local chain head.
+func (d *Downloader) localHeight() uint64 {
+	return d.blockchain.CurrentBlock().Header.Height
+}
+
+// findCommonAncestorHeight finds the height of the highest block the local
+// chain shares with the given peer, so the sync loop knows where to resume
+// from. The search is bounded by the height of the full chain's head, and in
+// every mode each local hash is read from the header index first, falling
+// back to stored full blocks (see hashAtHeight).
+func (d *Downloader) findCommonAncestorHeight(p *peerConnection, remoteHeight uint64) (uint64, error) {
+	localHeight := d.localHeight()
+	if localHeight == 0 {
+		return 0, nil
+	}
+
+	floor, ceil := uint64(0), localHeight
+	if remoteHeight < ceil {
+		ceil = remoteHeight
+	}
+
+	for floor < ceil {
+		mid := floor + (ceil-floor+1)/2
+
+		localHash, err := d.hashAtHeight(mid)
+		if err != nil {
+			ceil = mid - 1
+			continue
+		}
+
+		headers, err := p.peer.RequestHeadersByHashOrNumber(localHash, mid, 1, false)
+		if err != nil {
+			return 0, err
+		}
+
+		if len(headers) > 0 && headers[0].Hash().Equal(localHash) {
+			// The peer has this block too, so the ancestor is at least mid;
+			// keep searching higher.
+			floor = mid
+		} else {
+			// The peer diverges at mid (or never responded), so the ancestor
+			// must be lower.
+			ceil = mid - 1
+		}
+	}
+
+	return floor, nil
+}
+
+// maxHeaderFetch caps how many headers a single SyncHeaders round trip asks
+// a peer for.
+const maxHeaderFetch = 192
+
+// SyncHeaders pulls every header between the common ancestor with p and
+// remoteHeight, verifying each one links to the previous before indexing it
+// with storeHeader. It is the LightSync counterpart of a full block sync and
+// requires the downloader to be running in LightSync mode.
+func (d *Downloader) SyncHeaders(p *peerConnection, remoteHeight uint64) error {
+	if d.mode != LightSync {
+		return ErrLightSyncRequired
+	}
+
+	if d.consensus == nil {
+		return ErrConsensusEngineRequired
+	}
+
+	ancestor, err := d.findCommonAncestorHeight(p, remoteHeight)
+	if err != nil {
+		return err
+	}
+
+	parentHash, err := d.hashAtHeight(ancestor)
+	if err != nil {
+		return err
+	}
+
+	for height := ancestor + 1; height <= remoteHeight; {
+		amount := remoteHeight - height + 1
+		if amount > maxHeaderFetch {
+			amount = maxHeaderFetch
+		}
+
+		headers, err := p.peer.RequestHeadersByHashOrNumber(common.EmptyHash, height, int(amount), false)
+		if err != nil {
+			return err
+		}
+
+		if len(headers) == 0 {
+			return ErrUnknownHeader
+		}
+
+		for _, header := range headers {
+			if !header.PreviousBlockHash.Equal(parentHash) {
+				return ErrUnknownHeader
+			}
+
+			if err := d.consensus.VerifyHeader(header); err != nil {
+				return ErrInvalidHeader
+			}
+
+			d.storeHeader(header)
+			parentHash = header.Hash()
+			height++
+		}
+	}
+
+	return nil
+}
+
+// hashAtHeight returns the local header hash at the given height, reading
+// from the sparse LightSync index first and falling back to full blocks.
+func (d *Downloader) hashAtHeight(height uint64) (common.Hash, error) {
+	if header, ok := d.headerAt(height); ok {
+		return header.Hash(), nil
+	}
+
+	block, err := d.bcStore.GetBlockByHeight(height)
+	if err != nil {
+		return common.EmptyHash, err
+	}
+
+	return block.HeaderHash, nil
+}
+
+func (d *Downloader) headerAt(height uint64) (*types.BlockHeader, bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	header, ok := d.headers[height]
+	return header, ok
+}
+
+// GetHeaderByNumber returns the header at the given height from the
+// downloader's index, populated while syncing in LightSync mode, falling
+// back to the header of the stored full block at that height.
+func (d *Downloader) GetHeaderByNumber(height uint64) (*types.BlockHeader, error) {
+	if header, ok := d.headerAt(height); ok {
+		return header, nil
+	}
+
+	block, err := d.bcStore.GetBlockByHeight(height)
+	if err != nil {
+		return nil, ErrUnknownHeader
+	}
+
+	return block.Header, nil
+}
+
+// GetHeaderByHash returns the header with the given hash from the
+// downloader's index, populated while syncing in LightSync mode.
+func (d *Downloader) GetHeaderByHash(hash common.Hash) (*types.BlockHeader, error) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	for _, header := range d.headers {
+		if header.Hash().Equal(hash) {
+			return header, nil
+		}
+	}
+
+	return nil, ErrUnknownHeader
+}
+
+// VerifyProof verifies a Merkle proof returned by a full peer against the
+// state root recorded in the header at the given height, letting a
+// LightSync client trust a single key/value read without downloading the
+// whole state trie. It requires the downloader to be running LightSync.
+func (d *Downloader) VerifyProof(height uint64, key []byte, proof [][]byte, value []byte) (bool, error) {
+	if d.mode != LightSync {
+		return false, ErrLightSyncRequired
+	}
+
+	header, err := d.GetHeaderByNumber(height)
+	if err != nil {
+		return false, err
+	}
+
+	return verifyMerkleProof(header.StateHash, key, proof, value), nil
+}
+
+// storeHeader records a header fetched while syncing in LightSync mode.
+func (d *Downloader) storeHeader(header *types.BlockHeader) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.headers[header.Height] = header
+}
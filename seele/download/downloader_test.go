@@ -7,6 +7,7 @@ package downloader
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"io/ioutil"
 	"math/big"
 	"os"
@@ -56,7 +57,7 @@ func newTestTx(t *testing.T, amount int64, nonce uint64) *types.Transaction {
 	fromPrivKey, fromAddress := randomAccount(t)
 	_, toAddress := randomAccount(t)
 
-	tx, _ := types.NewTransaction(fromAddress, toAddress, big.NewInt(amount), big.NewInt(0), nonce)
+	tx, _ := types.NewTransaction(fromAddress, toAddress, big.NewInt(amount), big.NewInt(1), types.TxGas, nonce)
 	tx.Sign(fromPrivKey)
 
 	return tx
@@ -139,8 +140,8 @@ func (p TestPeer) Head() (hash common.Hash, td *big.Int) {
 }
 
 // RequestHeadersByHashOrNumber fetches a batch of blocks' headers
-func (p TestPeer) RequestHeadersByHashOrNumber(origin common.Hash, num uint64, amount int, reverse bool) error {
-	return nil
+func (p TestPeer) RequestHeadersByHashOrNumber(origin common.Hash, num uint64, amount int, reverse bool) ([]*types.BlockHeader, error) {
+	return nil, nil
 }
 
 // RequestBlocksByHashOrNumber fetches a batch of blocks
@@ -148,6 +149,61 @@ func (p TestPeer) RequestBlocksByHashOrNumber(origin common.Hash, num uint64, am
 	return nil
 }
 
+// RequestReceiptsByHash fetches the receipts for the given block hashes
+func (p TestPeer) RequestReceiptsByHash(hashes []common.Hash) error {
+	return nil
+}
+
+// RequestProof fetches a Merkle proof for the given key against the given state root
+func (p TestPeer) RequestProof(root common.Hash, key []byte) error {
+	return nil
+}
+
+// buildLocalChain writes height blocks on top of genesis directly into bc's
+// store, returning them in ascending height order (index 0 is height 1), so
+// tests can exercise codepaths that need localHeight() > 0.
+func buildLocalChain(t *testing.T, bc *core.Blockchain, db database.Database, height uint64) []*types.Block {
+	bcStore := bc.GetStore()
+	parentHash := bc.CurrentBlock().HeaderHash
+
+	blocks := make([]*types.Block, 0, height)
+	for h := uint64(1); h <= height; h++ {
+		block := newTestBlock(t, parentHash, h, db, h, int64(h))
+		if err := bcStore.PutBlock(block, int64(h), true); err != nil {
+			t.Fatal(err)
+		}
+
+		blocks = append(blocks, block)
+		parentHash = block.HeaderHash
+	}
+
+	return blocks
+}
+
+// divergingPeer matches a local chain's headers up to (but not including)
+// divergeAt, then returns a forged header for every height at or past it, so
+// tests can verify findCommonAncestorHeight actually narrows on a real
+// mismatch instead of stopping after one probe.
+type divergingPeer struct {
+	TestPeer
+	local     []*types.Block // index i holds the block at height i+1
+	divergeAt uint64
+}
+
+func (p divergingPeer) RequestHeadersByHashOrNumber(origin common.Hash, num uint64, amount int, reverse bool) ([]*types.BlockHeader, error) {
+	if num >= p.divergeAt {
+		return []*types.BlockHeader{{
+			PreviousBlockHash: common.EmptyHash,
+			Height:            num,
+			Difficulty:        big.NewInt(0),
+			CreateTimestamp:   big.NewInt(0),
+			ExtraData:         []byte("forged"),
+		}}, nil
+	}
+
+	return []*types.BlockHeader{p.local[num-1].Header}, nil
+}
+
 func Test_findCommonAncestorHeight_localHeightIsZero(t *testing.T) {
 	db, dispose := newTestDatabase()
 	defer dispose()
@@ -159,3 +215,158 @@ func Test_findCommonAncestorHeight_localHeightIsZero(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, uint64(0), ancestorHeight)
 }
+
+func Test_Downloader_defaultsToFullSync(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+	dl := newTestDownloader(db)
+	assert.Equal(t, FullSync, dl.SyncMode())
+}
+
+func Test_Downloader_VerifyProof_requiresLightSync(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+	bc := newTestBlockchain(db)
+	dl := NewDownloaderWithMode(bc, FullSync)
+
+	_, err := dl.VerifyProof(0, nil, nil, nil)
+	assert.Equal(t, ErrLightSyncRequired, err)
+}
+
+func Test_Downloader_GetHeaderByNumber_unknown(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+	bc := newTestBlockchain(db)
+	dl := NewDownloaderWithMode(bc, LightSync)
+
+	_, err := dl.GetHeaderByNumber(12345)
+	assert.Equal(t, ErrUnknownHeader, err)
+}
+
+func Test_Downloader_SyncHeaders_requiresLightSync(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+	bc := newTestBlockchain(db)
+	dl := NewDownloaderWithMode(bc, FullSync)
+
+	var testPeer TestPeer
+	p := newPeerConn(testPeer, "test")
+	err := dl.SyncHeaders(p, 10)
+	assert.Equal(t, ErrLightSyncRequired, err)
+}
+
+func Test_Downloader_SyncHeaders_requiresConsensusEngine(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+	bc := newTestBlockchain(db)
+	dl := NewDownloaderWithMode(bc, LightSync)
+
+	var testPeer TestPeer
+	p := newPeerConn(testPeer, "test")
+	err := dl.SyncHeaders(p, 10)
+	assert.Equal(t, ErrConsensusEngineRequired, err)
+}
+
+// acceptAllConsensusEngine is a ConsensusEngine stub that accepts every
+// header, for tests that aren't exercising consensus rejection itself.
+type acceptAllConsensusEngine struct{}
+
+func (acceptAllConsensusEngine) VerifyHeader(header *types.BlockHeader) error {
+	return nil
+}
+
+// rejectingConsensusEngine is a ConsensusEngine stub that rejects every
+// header, so SyncHeaders' verification step can be tested in isolation.
+type rejectingConsensusEngine struct{}
+
+func (rejectingConsensusEngine) VerifyHeader(header *types.BlockHeader) error {
+	return errors.New("rejected")
+}
+
+// chainPeer is a TestPeer that hands back a canned chain of headers starting
+// right after genesis, so SyncHeaders has something real to fetch and index.
+type chainPeer struct {
+	TestPeer
+	headers []*types.BlockHeader
+}
+
+func (p chainPeer) RequestHeadersByHashOrNumber(origin common.Hash, num uint64, amount int, reverse bool) ([]*types.BlockHeader, error) {
+	var result []*types.BlockHeader
+	for _, header := range p.headers {
+		if header.Height >= num && len(result) < amount {
+			result = append(result, header)
+		}
+	}
+	return result, nil
+}
+
+func Test_Downloader_SyncHeaders_storesFetchedHeaders(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+	bc := newTestBlockchain(db)
+	dl := NewLightDownloader(bc, acceptAllConsensusEngine{})
+
+	genesisHash := bc.CurrentBlock().HeaderHash
+
+	header1 := &types.BlockHeader{
+		PreviousBlockHash: genesisHash,
+		Height:            1,
+		Difficulty:        big.NewInt(1),
+		CreateTimestamp:   big.NewInt(1),
+	}
+	header2 := &types.BlockHeader{
+		PreviousBlockHash: header1.Hash(),
+		Height:            2,
+		Difficulty:        big.NewInt(1),
+		CreateTimestamp:   big.NewInt(1),
+	}
+
+	peer := chainPeer{headers: []*types.BlockHeader{header1, header2}}
+	p := newPeerConn(peer, "test")
+
+	err := dl.SyncHeaders(p, 2)
+	assert.Equal(t, nil, err)
+
+	stored, err := dl.GetHeaderByNumber(2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, header2.Hash(), stored.Hash())
+}
+
+func Test_Downloader_SyncHeaders_rejectsHeaderFailingConsensus(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+	bc := newTestBlockchain(db)
+	dl := NewLightDownloader(bc, rejectingConsensusEngine{})
+
+	genesisHash := bc.CurrentBlock().HeaderHash
+	header1 := &types.BlockHeader{
+		PreviousBlockHash: genesisHash,
+		Height:            1,
+		Difficulty:        big.NewInt(1),
+		CreateTimestamp:   big.NewInt(1),
+	}
+
+	peer := chainPeer{headers: []*types.BlockHeader{header1}}
+	p := newPeerConn(peer, "test")
+
+	err := dl.SyncHeaders(p, 1)
+	assert.Equal(t, ErrInvalidHeader, err)
+
+	_, err = dl.GetHeaderByNumber(1)
+	assert.Equal(t, ErrUnknownHeader, err)
+}
+
+func Test_findCommonAncestorHeight_narrowsToDivergencePoint(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+	bc := newTestBlockchain(db)
+	dl := NewDownloaderWithMode(bc, FullSync)
+
+	local := buildLocalChain(t, bc, db, 5)
+	peer := divergingPeer{local: local, divergeAt: 4}
+	p := newPeerConn(peer, "test")
+
+	ancestorHeight, err := dl.findCommonAncestorHeight(p, 5)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(3), ancestorHeight)
+}
@@ -0,0 +1,58 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package downloader
+
+import (
+	"math/big"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+// Peer is the set of methods the downloader needs from a connected node to
+// sync the chain, whether fetching full blocks or, for LightSync, only
+// headers and Merkle proofs.
+type Peer interface {
+	// Head retrieves a copy of the peer's current head hash and total difficulty.
+	Head() (hash common.Hash, td *big.Int)
+
+	// RequestHeadersByHashOrNumber fetches a batch of block headers starting
+	// at origin (by hash) or num (by height) and returns them directly, so
+	// LightSync can drive a bisection/fetch loop without a separate
+	// asynchronous delivery path.
+	RequestHeadersByHashOrNumber(origin common.Hash, num uint64, amount int, reverse bool) ([]*types.BlockHeader, error)
+
+	// RequestBlocksByHashOrNumber fetches a batch of full blocks.
+	RequestBlocksByHashOrNumber(origin common.Hash, num uint64, amount int) error
+
+	// RequestReceiptsByHash fetches the receipts for the given block hashes.
+	RequestReceiptsByHash(hashes []common.Hash) error
+
+	// RequestProof fetches a Merkle proof for the given key against the
+	// given state root, so a LightSync peer can verify a read without
+	// downloading the whole state trie.
+	RequestProof(root common.Hash, key []byte) error
+}
+
+// peerConnection wraps a Peer with the bookkeeping the downloader needs to
+// identify it in logs and pending-request maps.
+type peerConnection struct {
+	id   string
+	peer Peer
+}
+
+// newPeerConn wraps the given peer with the given human-readable id.
+func newPeerConn(peer Peer, id string) *peerConnection {
+	return &peerConnection{
+		id:   id,
+		peer: peer,
+	}
+}
+
+// Head retrieves a copy of the peer's current head hash and total difficulty.
+func (p *peerConnection) Head() (common.Hash, *big.Int) {
+	return p.peer.Head()
+}
@@ -0,0 +1,36 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package downloader
+
+import (
+	"bytes"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+// verifyMerkleProof recomputes the root hash implied by walking key/value up
+// through proof, a bottom-up list of sibling hashes, and compares it against
+// root. It lets a LightSync client trust a single state read served by a
+// full peer without fetching the whole trie.
+func verifyMerkleProof(root common.Hash, key []byte, proof [][]byte, value []byte) bool {
+	current := crypto.MustHash(append(append([]byte{}, key...), value...))
+
+	for _, sibling := range proof {
+		combined := make([]byte, 0, len(current.Bytes())+len(sibling))
+		if bytes.Compare(current.Bytes(), sibling) <= 0 {
+			combined = append(combined, current.Bytes()...)
+			combined = append(combined, sibling...)
+		} else {
+			combined = append(combined, sibling...)
+			combined = append(combined, current.Bytes()...)
+		}
+
+		current = crypto.MustHash(combined)
+	}
+
+	return current.Equal(root)
+}